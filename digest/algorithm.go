@@ -0,0 +1,82 @@
+package digest
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+)
+
+// Algorithm describes a digest algorithm registered with
+// RegisterDigestAlgorithm, following the pattern used by
+// opencontainers/go-digest.
+type Algorithm interface {
+	// New returns a new hash.Hash computing this algorithm's digest.
+	New() hash.Hash
+	// Size returns the number of bytes in a fully computed digest.
+	Size() int
+	// Available reports whether the algorithm can actually be used. It
+	// lets an entry stay registered (e.g. for error messages) while being
+	// disabled, for instance behind a build tag.
+	Available() bool
+}
+
+// registeredAlgorithm is the Algorithm implementation created by
+// RegisterDigestAlgorithm.
+type registeredAlgorithm struct {
+	new  func() hash.Hash
+	size int
+}
+
+func (a registeredAlgorithm) New() hash.Hash  { return a.new() }
+func (a registeredAlgorithm) Size() int       { return a.size }
+func (a registeredAlgorithm) Available() bool { return a.new != nil }
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = map[DigestAlgorithm]Algorithm{}
+)
+
+// RegisterDigestAlgorithm registers name so it can be used with AddDigest,
+// AddDigests, verifyDigest, VerifyDigests and the streaming variants. size
+// is the number of bytes a digest produced by ctor occupies; digests that
+// decode to any other length are rejected with ErrDigestInvalidLength. This
+// lets downstream users plug in algorithms such as BLAKE2 or SHA3, or vendor
+// hashes, without patching this package. It is safe to call concurrently
+// with digest traffic and with itself.
+func RegisterDigestAlgorithm(name DigestAlgorithm, ctor func() hash.Hash, size int) {
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+	algorithms[name] = registeredAlgorithm{new: ctor, size: size}
+}
+
+func init() {
+	RegisterDigestAlgorithm(SHA256, sha256.New, sha256.Size)
+	RegisterDigestAlgorithm(SHA512, sha512.New, sha512.Size)
+}
+
+// getAlgorithm looks up the Algorithm registered for algo.
+func getAlgorithm(algo DigestAlgorithm) (Algorithm, error) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+	def, ok := algorithms[algo]
+	if !ok || !def.Available() {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algo)
+	}
+	return def, nil
+}
+
+// digestToDef resolves a Content-Digest member name (e.g. "sha-256"),
+// matched case-insensitively, back to its DigestAlgorithm and Algorithm.
+func digestToDef(name string) (DigestAlgorithm, Algorithm, error) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+	for algo, def := range algorithms {
+		if strings.EqualFold(string(algo), name) && def.Available() {
+			return algo, def, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, name)
+}
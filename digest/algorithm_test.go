@@ -0,0 +1,76 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestRegisterDigestAlgorithm(t *testing.T) {
+	const MD5 DigestAlgorithm = "MD5"
+	RegisterDigestAlgorithm(MD5, md5.New, md5.Size)
+	defer func() {
+		algorithmsMu.Lock()
+		delete(algorithms, MD5)
+		algorithmsMu.Unlock()
+	}()
+
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	body := []byte("johnny grab your gun")
+	if err := AddDigest(r, MD5, body, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := verifyDigest(r, bytes.NewBuffer(body), true); err != nil {
+		t.Fatalf("unexpected verify error: %s", err)
+	}
+}
+
+// TestRegisterDigestAlgorithmConcurrent exercises RegisterDigestAlgorithm
+// running concurrently with digest traffic under -race: registration must
+// not race with lookups done by verifyDigest/digestToDef.
+func TestRegisterDigestAlgorithmConcurrent(t *testing.T) {
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	body := []byte("johnny grab your gun")
+	if err := AddDigest(r, SHA256, body, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := DigestAlgorithm(fmt.Sprintf("CONCURRENT-%d", i))
+			RegisterDigestAlgorithm(name, md5.New, md5.Size)
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := verifyDigest(r, bytes.NewBuffer(body), true); err != nil {
+			t.Errorf("unexpected verify error: %s", err)
+		}
+	}()
+	wg.Wait()
+
+	algorithmsMu.Lock()
+	for i := 0; i < 8; i++ {
+		delete(algorithms, DigestAlgorithm(fmt.Sprintf("CONCURRENT-%d", i)))
+	}
+	algorithmsMu.Unlock()
+}
+
+func TestDecodeDigestValueInvalidLength(t *testing.T) {
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	// A validly base64-encoded value that is far shorter than sha-256's
+	// 32-byte digest.
+	r.Header.Set("content-digest", "sha-256=:aGk=:")
+	err := verifyDigest(r, bytes.NewBuffer([]byte("hi")), true)
+	if !errors.Is(err, ErrDigestInvalidLength) {
+		t.Fatalf("expected ErrDigestInvalidLength, got: %s", err)
+	}
+}
@@ -0,0 +1,140 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// headerReprDigest is the RFC 9530 companion to Content-Digest that covers
+// the selected representation after any content-coding has been applied,
+// rather than the message body as transferred.
+const headerReprDigest = "Repr-Digest"
+
+// DigestsOptions configures AddDigests and VerifyDigests.
+type DigestsOptions struct {
+	// WithPadding selects padded ("=") vs unpadded base64 for encoded
+	// digest values, as with AddDigest/verifyDigest.
+	WithPadding bool
+	// Repr selects the Repr-Digest header instead of Content-Digest.
+	Repr bool
+}
+
+func (o DigestsOptions) header() string {
+	if o.Repr {
+		return headerReprDigest
+	}
+	return headerContentDigest
+}
+
+// AddDigests computes digests of body under each of algos and sets them as
+// a single Content-Digest (or, with opts.Repr, Repr-Digest) dictionary field
+// value, per RFC 9530 §3.1. It returns an error if the header is already
+// set, if algos is empty, or if any algorithm is not supported.
+func AddDigests(r *http.Request, algos []DigestAlgorithm, body []byte, opts DigestsOptions) error {
+	header := opts.header()
+	if r.Header.Get(header) != "" {
+		return ErrDigestAlreadySet
+	}
+	if len(algos) == 0 {
+		return fmt.Errorf("digest: at least one algorithm is required")
+	}
+	members := make([]string, len(algos))
+	for i, algo := range algos {
+		def, err := getAlgorithm(algo)
+		if err != nil {
+			return err
+		}
+		h := def.New()
+		h.Write(body)
+		members[i] = formatContentDigest(algo, h.Sum(nil), opts.WithPadding)
+	}
+	r.Header.Set(header, strings.Join(members, ", "))
+	return nil
+}
+
+// VerifyDigests validates the Content-Digest (or, with opts.Repr,
+// Repr-Digest) dictionary header on r against body. When the header lists
+// several algorithms, the strongest one this package recognizes is
+// validated; an error is returned if none of the offered algorithms are
+// supported.
+func VerifyDigests(r *http.Request, body []byte, opts DigestsOptions) error {
+	header := opts.header()
+	value := r.Header.Get(header)
+	if value == "" {
+		return ErrMissingDigest
+	}
+	members, err := parseDigestDict(value)
+	if err != nil {
+		return err
+	}
+	algo, def, encoded, err := strongestSupported(members)
+	if err != nil {
+		return err
+	}
+	sum, err := decodeDigestValue(def, encoded, opts.WithPadding)
+	if err != nil {
+		return err
+	}
+	h := def.New()
+	h.Write(body)
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		return fmt.Errorf("%w: %s", ErrDigestMismatch, algo)
+	}
+	return nil
+}
+
+// digestMember is one name=:value: entry of a Content-Digest or Repr-Digest
+// dictionary field value.
+type digestMember struct {
+	name  string
+	value string
+}
+
+// parseDigestDict splits a Content-Digest/Repr-Digest dictionary field value
+// into its members. RFC 9530 dictionaries list members comma-separated, each
+// of the form "algo=:base64:".
+func parseDigestDict(header string) ([]digestMember, error) {
+	parts := strings.Split(header, ",")
+	members := make([]digestMember, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, err := parseContentDigest(part)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, digestMember{name: name, value: value})
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%w: empty dictionary", ErrMalformedDigest)
+	}
+	return members, nil
+}
+
+// strongestSupported picks the algorithm with the largest digest size among
+// members that this package recognizes, used as a proxy for cryptographic
+// strength (e.g. SHA-512 over SHA-256).
+func strongestSupported(members []digestMember) (DigestAlgorithm, Algorithm, string, error) {
+	var (
+		best      DigestAlgorithm
+		bestDef   Algorithm
+		bestValue string
+	)
+	for _, m := range members {
+		algo, def, err := digestToDef(m.name)
+		if err != nil {
+			continue
+		}
+		if bestDef == nil || def.Size() > bestDef.Size() {
+			best, bestDef, bestValue = algo, def, m.value
+		}
+	}
+	if bestDef == nil {
+		return "", nil, "", fmt.Errorf("%w: no recognized algorithm offered", ErrUnsupportedAlgorithm)
+	}
+	return best, bestDef, bestValue, nil
+}
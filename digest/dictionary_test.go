@@ -0,0 +1,69 @@
+package digest
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAddDigests(t *testing.T) {
+	body := []byte("johnny grab your gun")
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	if err := AddDigests(r, []DigestAlgorithm{SHA256, SHA512}, body, DigestsOptions{WithPadding: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:, " +
+		"sha-512=:SryuzzRzrReZE-SGQIhyuXqB44VIpglbwlhvXubKl8qMtxm95Jg90nlIZ-Zhu0RWShhIczk57yatCvjWY_4r4w==:"
+	if got := r.Header.Get("content-digest"); got != want {
+		t.Fatalf("unexpected digest: want %s, got %s", want, got)
+	}
+}
+
+func TestAddDigestsRepr(t *testing.T) {
+	body := []byte("johnny grab your gun")
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	if err := AddDigests(r, []DigestAlgorithm{SHA256}, body, DigestsOptions{WithPadding: true, Repr: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := r.Header.Get("content-digest"); got != "" {
+		t.Fatalf("expected no content-digest header, got %s", got)
+	}
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if got := r.Header.Get("repr-digest"); got != want {
+		t.Fatalf("unexpected repr-digest: want %s, got %s", want, got)
+	}
+}
+
+func TestAddDigestsErrors(t *testing.T) {
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	if err := AddDigests(r, nil, []byte("x"), DigestsOptions{}); err == nil {
+		t.Fatal("expected error for empty algorithm list")
+	}
+	if err := AddDigests(r, []DigestAlgorithm{"MD5"}, []byte("x"), DigestsOptions{}); err == nil {
+		t.Fatal("expected error for unsupported algorithm")
+	}
+}
+
+func TestVerifyDigestsStrongestWins(t *testing.T) {
+	body := []byte("johnny grab your gun")
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	r.Header.Set("content-digest",
+		"sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:, "+
+			"sha-512=:SryuzzRzrReZE-SGQIhyuXqB44VIpglbwlhvXubKl8qMtxm95Jg90nlIZ-Zhu0RWShhIczk57yatCvjWY_4r4w==:")
+	if err := VerifyDigests(r, body, DigestsOptions{WithPadding: true}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r2, _ := http.NewRequest("POST", "example.com", nil)
+	r2.Header.Set("content-digest", "md5=:poo:, sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:")
+	if err := VerifyDigests(r2, body, DigestsOptions{WithPadding: true}); err != nil {
+		t.Fatalf("unexpected error ignoring unsupported entry: %s", err)
+	}
+}
+
+func TestVerifyDigestsNoneSupported(t *testing.T) {
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	r.Header.Set("content-digest", "md5=:poo:")
+	if err := VerifyDigests(r, []byte("x"), DigestsOptions{}); err == nil {
+		t.Fatal("expected error when no offered algorithm is supported")
+	}
+}
@@ -0,0 +1,144 @@
+// Package digest computes and verifies the HTTP Content-Digest header
+// (RFC 9530) used alongside httpsig request/response signing.
+package digest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DigestAlgorithm identifies a digest algorithm usable with the
+// Content-Digest header.
+type DigestAlgorithm string
+
+// Algorithms registered by default; see RegisterDigestAlgorithm for adding
+// more.
+const (
+	SHA256 DigestAlgorithm = "SHA-256"
+	SHA512 DigestAlgorithm = "SHA-512"
+)
+
+// Errors returned by the functions of this package.
+var (
+	ErrDigestAlreadySet     = errors.New("digest: content-digest header already set")
+	ErrUnsupportedAlgorithm = errors.New("digest: unsupported digest algorithm")
+	ErrMalformedDigest      = errors.New("digest: malformed content-digest header")
+	ErrMissingDigest        = errors.New("digest: missing content-digest header")
+	ErrDigestMismatch       = errors.New("digest: computed digest does not match content-digest header")
+	ErrDigestInvalidLength  = errors.New("digest: decoded digest does not match algorithm size")
+	ErrBodyTooLarge         = errors.New("digest: body exceeds configured limit")
+)
+
+const headerContentDigest = "Content-Digest"
+
+func encodeDigest(sum []byte, withPadding bool) string {
+	if withPadding {
+		return base64.URLEncoding.EncodeToString(sum)
+	}
+	return base64.RawURLEncoding.EncodeToString(sum)
+}
+
+// decodeDigestValue base64-decodes value and validates that the result is
+// exactly algo.Size() bytes, rejecting malformed-but-base64-valid digests
+// early.
+func decodeDigestValue(algo Algorithm, value string, withPadding bool) ([]byte, error) {
+	enc := base64.URLEncoding
+	if !withPadding {
+		enc = base64.RawURLEncoding
+	}
+	sum, err := enc.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedDigest, err)
+	}
+	if len(sum) != algo.Size() {
+		return nil, fmt.Errorf("%w: got %d bytes, want %d", ErrDigestInvalidLength, len(sum), algo.Size())
+	}
+	return sum, nil
+}
+
+// AddDigest computes the digest of body using algo and sets the resulting
+// Content-Digest header on r. It returns an error if r already carries a
+// Content-Digest header, or if algo is not supported.
+func AddDigest(r *http.Request, algo DigestAlgorithm, body []byte, withPadding bool) error {
+	if r.Header.Get(headerContentDigest) != "" {
+		return ErrDigestAlreadySet
+	}
+	def, err := getAlgorithm(algo)
+	if err != nil {
+		return err
+	}
+	h := def.New()
+	h.Write(body)
+	value := formatContentDigest(algo, h.Sum(nil), withPadding)
+	r.Header.Set(headerContentDigest, value)
+	return nil
+}
+
+func formatContentDigest(algo DigestAlgorithm, sum []byte, withPadding bool) string {
+	return fmt.Sprintf("%s=:%s:", strings.ToLower(string(algo)), encodeDigest(sum, withPadding))
+}
+
+// verifyDigest validates the Content-Digest header on r against body. The
+// header may be a dictionary listing several algorithms (RFC 9530 §3.1); the
+// strongest one this package recognizes is validated, and an error is
+// returned if none of the offered algorithms are supported.
+func verifyDigest(r *http.Request, body *bytes.Buffer, withPadding bool) error {
+	header := r.Header.Get(headerContentDigest)
+	if header == "" {
+		return ErrMissingDigest
+	}
+	members, err := parseDigestDict(header)
+	if err != nil {
+		return err
+	}
+	algo, def, encoded, err := strongestSupported(members)
+	if err != nil {
+		return err
+	}
+	sum, err := decodeDigestValue(def, encoded, withPadding)
+	if err != nil {
+		return err
+	}
+	h := def.New()
+	h.Write(body.Bytes())
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		return fmt.Errorf("%w: %s", ErrDigestMismatch, algo)
+	}
+	return nil
+}
+
+// readAllLimited reads r fully, as io.ReadAll does, but fails with
+// ErrBodyTooLarge instead of exhausting memory if r produces more than limit
+// bytes. limit <= 0 means no limit.
+func readAllLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+// parseContentDigest splits a single-member Content-Digest dictionary value
+// of the form "algo=:base64:" into the algorithm name and base64 payload.
+func parseContentDigest(header string) (name, value string, err error) {
+	eq := strings.IndexByte(header, '=')
+	if eq < 0 {
+		return "", "", fmt.Errorf("%w: missing '='", ErrMalformedDigest)
+	}
+	name, rest := header[:eq], header[eq+1:]
+	if len(rest) < 2 || rest[0] != ':' || rest[len(rest)-1] != ':' {
+		return "", "", fmt.Errorf("%w: expected a byte sequence", ErrMalformedDigest)
+	}
+	return name, rest[1 : len(rest)-1], nil
+}
@@ -0,0 +1,73 @@
+package digest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MiddlewareOptions configures Middleware.
+type MiddlewareOptions struct {
+	DigestsOptions
+	// Advertise lists the algorithms, in descending preference, to
+	// advertise to clients via the Want-Content-Digest response header so
+	// they can honor the server's preference on subsequent requests. Left
+	// unset, no Want-Content-Digest header is sent.
+	Advertise []DigestAlgorithm
+	// MaxBodyBytes caps how much of the request body Middleware will
+	// buffer in order to verify its digest. Requests whose body exceeds it
+	// are rejected with 413 Request Entity Too Large before next is
+	// called. Zero means no limit, which is almost never what you want for
+	// a handler exposed to untrusted clients.
+	MaxBodyBytes int64
+}
+
+// Middleware returns an http.Handler that verifies the Content-Digest (or,
+// with opts.Repr, Repr-Digest) header on incoming requests before calling
+// next. Requests without a body are passed through unchanged. A digest
+// mismatch, or a missing or malformed digest header, is rejected with 400
+// Bad Request; a header offering no supported algorithm is rejected with
+// 422 Unprocessable Entity; a body exceeding opts.MaxBodyBytes is rejected
+// with 413 Request Entity Too Large. On
+// success, the fully-read body is handed to next via a reset r.Body.
+func Middleware(next http.Handler, opts MiddlewareOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(opts.Advertise) > 0 {
+			w.Header().Set(headerWantContentDigest, FormatWantContentDigest(opts.Advertise...))
+		}
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reqBody := r.Body
+		if opts.MaxBodyBytes > 0 {
+			reqBody = http.MaxBytesReader(w, r.Body, opts.MaxBodyBytes)
+		}
+		body, err := io.ReadAll(reqBody)
+		r.Body.Close()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "digest: request body exceeds limit", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "digest: reading request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := VerifyDigests(r, body, opts.DigestsOptions); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrUnsupportedAlgorithm) {
+				status = http.StatusUnprocessableEntity
+			}
+			http.Error(w, "digest: "+err.Error(), status)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package digest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareVerifiesAndForwards(t *testing.T) {
+	var gotBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusOK)
+	})
+	h := Middleware(next, MiddlewareOptions{DigestsOptions: DigestsOptions{WithPadding: true}})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("johnny grab your gun"))
+	req.Header.Set("content-digest", "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if gotBody != "johnny grab your gun" {
+		t.Fatalf("unexpected body forwarded to next: %s", gotBody)
+	}
+}
+
+func TestMiddlewareRejectsMismatch(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next should not be called on a digest mismatch")
+	})
+	h := Middleware(next, MiddlewareOptions{DigestsOptions: DigestsOptions{WithPadding: true}})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("a different body"))
+	req.Header.Set("content-digest", "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareRejectsUnsupportedAlgorithm(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next should not be called for an unsupported algorithm")
+	})
+	h := Middleware(next, MiddlewareOptions{})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("johnny grab your gun"))
+	req.Header.Set("content-digest", "md5=:poo:")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareRejectsOversizedBody(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next should not be called for an oversized body")
+	})
+	h := Middleware(next, MiddlewareOptions{MaxBodyBytes: 4})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("johnny grab your gun"))
+	req.Header.Set("content-digest", "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareAdvertisesWantContentDigest(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := Middleware(next, MiddlewareOptions{Advertise: []DigestAlgorithm{SHA512, SHA256}})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	want := "sha-512=2, sha-256=1"
+	if got := rec.Header().Get("Want-Content-Digest"); got != want {
+		t.Fatalf("unexpected Want-Content-Digest: want %s, got %s", want, got)
+	}
+}
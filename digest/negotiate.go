@@ -0,0 +1,59 @@
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// headerWantContentDigest is RFC 9530 §4's negotiation field: a sender uses
+// it to advertise the Content-Digest algorithms it wants or accepts, each
+// with an integer preference (higher is more preferred).
+const headerWantContentDigest = "Want-Content-Digest"
+
+// FormatWantContentDigest encodes algos, in descending preference order, as
+// a Want-Content-Digest field value.
+func FormatWantContentDigest(algos ...DigestAlgorithm) string {
+	members := make([]string, len(algos))
+	for i, algo := range algos {
+		members[i] = fmt.Sprintf("%s=%d", strings.ToLower(string(algo)), len(algos)-i)
+	}
+	return strings.Join(members, ", ")
+}
+
+// ParseWantContentDigest decodes a Want-Content-Digest field value into the
+// algorithms it names that this package recognizes, ordered from most to
+// least preferred. Members with no weight default to a preference of 1;
+// unrecognized algorithms are skipped.
+func ParseWantContentDigest(header string) []DigestAlgorithm {
+	type weighted struct {
+		algo   DigestAlgorithm
+		weight int
+	}
+	var candidates []weighted
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weight := part, 1
+		if eq := strings.IndexByte(part, '='); eq >= 0 {
+			name = part[:eq]
+			if w, err := strconv.Atoi(strings.TrimSpace(part[eq+1:])); err == nil {
+				weight = w
+			}
+		}
+		algo, _, err := digestToDef(name)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, weighted{algo, weight})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].weight > candidates[j].weight })
+	algos := make([]DigestAlgorithm, len(candidates))
+	for i, c := range candidates {
+		algos[i] = c.algo
+	}
+	return algos
+}
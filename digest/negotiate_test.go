@@ -0,0 +1,32 @@
+package digest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatAndParseWantContentDigest(t *testing.T) {
+	header := FormatWantContentDigest(SHA512, SHA256)
+	want := "sha-512=2, sha-256=1"
+	if header != want {
+		t.Fatalf("unexpected header: want %s, got %s", want, header)
+	}
+	got := ParseWantContentDigest(header)
+	if !reflect.DeepEqual(got, []DigestAlgorithm{SHA512, SHA256}) {
+		t.Fatalf("unexpected parse result: %v", got)
+	}
+}
+
+func TestParseWantContentDigestSkipsUnknown(t *testing.T) {
+	got := ParseWantContentDigest("md5=5, sha-256=1")
+	if !reflect.DeepEqual(got, []DigestAlgorithm{SHA256}) {
+		t.Fatalf("unexpected parse result: %v", got)
+	}
+}
+
+func TestParseWantContentDigestDefaultWeight(t *testing.T) {
+	got := ParseWantContentDigest("sha-256, sha-512=5")
+	if !reflect.DeepEqual(got, []DigestAlgorithm{SHA512, SHA256}) {
+		t.Fatalf("unexpected parse result: %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// AddDigestReader computes the Content-Digest of body as it streams through
+// the returned io.ReadCloser, which is also assigned to r.Body. Unlike
+// AddDigest, the digest is not known until body reaches EOF, so it cannot in
+// general be placed in the leading Content-Digest header:
+//
+//   - if r.GetBody is set, the body is assumed cheap to re-read (e.g. it
+//     wraps an in-memory buffer or a seekable file) and is hashed eagerly
+//     through that second copy so the header can be set up front, exactly
+//     as AddDigest would;
+//   - otherwise the digest is only known after body is drained, so it is
+//     carried as an HTTP trailer: r.Trailer is arranged to declare
+//     Content-Digest, and the trailer value is stamped once the wrapped
+//     reader reports EOF. Callers whose peer does not support trailers (or
+//     who need the header before any body bytes are sent) must buffer body
+//     themselves and call AddDigest instead.
+func AddDigestReader(r *http.Request, algo DigestAlgorithm, body io.Reader, withPadding bool) (io.ReadCloser, error) {
+	if r.Header.Get(headerContentDigest) != "" {
+		return nil, ErrDigestAlreadySet
+	}
+	def, err := getAlgorithm(algo)
+	if err != nil {
+		return nil, err
+	}
+	if r.GetBody != nil {
+		return addDigestFromGetBody(r, algo, def, body, withPadding)
+	}
+	return addDigestTrailer(r, algo, def, body, withPadding), nil
+}
+
+func addDigestFromGetBody(r *http.Request, algo DigestAlgorithm, def Algorithm, body io.Reader, withPadding bool) (io.ReadCloser, error) {
+	copy, err := r.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("digest: obtaining GetBody copy: %w", err)
+	}
+	defer copy.Close()
+	h := def.New()
+	if _, err := io.Copy(h, copy); err != nil {
+		return nil, fmt.Errorf("digest: hashing request body: %w", err)
+	}
+	r.Header.Set(headerContentDigest, formatContentDigest(algo, h.Sum(nil), withPadding))
+	rc := toReadCloser(body)
+	r.Body = rc
+	return rc, nil
+}
+
+func addDigestTrailer(r *http.Request, algo DigestAlgorithm, def Algorithm, body io.Reader, withPadding bool) io.ReadCloser {
+	if r.Trailer == nil {
+		r.Trailer = make(http.Header)
+	}
+	r.Trailer.Set(headerContentDigest, "")
+	rc := &digestingBody{
+		req:         r,
+		algo:        algo,
+		hash:        def.New(),
+		withPadding: withPadding,
+		closer:      toCloser(body),
+	}
+	rc.tee = io.TeeReader(body, rc.hash)
+	r.Body = rc
+	return rc
+}
+
+// digestingBody tees r's body through a hash and, once fully read, stamps
+// the resulting digest onto r.Trailer.
+type digestingBody struct {
+	req         *http.Request
+	algo        DigestAlgorithm
+	hash        hash.Hash
+	tee         io.Reader
+	closer      io.Closer
+	withPadding bool
+	stamped     bool
+}
+
+func (d *digestingBody) Read(p []byte) (int, error) {
+	n, err := d.tee.Read(p)
+	if err == io.EOF && !d.stamped {
+		d.stamped = true
+		d.req.Trailer.Set(headerContentDigest, formatContentDigest(d.algo, d.hash.Sum(nil), d.withPadding))
+	}
+	return n, err
+}
+
+func (d *digestingBody) Close() error {
+	if d.closer != nil {
+		return d.closer.Close()
+	}
+	return nil
+}
+
+func toReadCloser(r io.Reader) io.ReadCloser {
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc
+	}
+	return io.NopCloser(r)
+}
+
+func toCloser(r io.Reader) io.Closer {
+	if c, ok := r.(io.Closer); ok {
+		return c
+	}
+	return nil
+}
+
+// VerifyDigestReader streams body while validating it against the
+// Content-Digest header already present on r, without buffering the whole
+// payload. A missing, malformed or unsupported header is reported at once
+// as VerifyDigestReader's own error. The digest itself can only be
+// confirmed once body is exhausted, so a mismatch is reported as the error
+// from the final Read instead of io.EOF; callers must drain the returned
+// Reader to completion (e.g. with io.Copy) to learn whether verification
+// succeeded.
+func VerifyDigestReader(r *http.Request, body io.Reader, withPadding bool) (io.Reader, error) {
+	header := r.Header.Get(headerContentDigest)
+	if header == "" {
+		return nil, ErrMissingDigest
+	}
+	members, err := parseDigestDict(header)
+	if err != nil {
+		return nil, err
+	}
+	algo, def, encoded, err := strongestSupported(members)
+	if err != nil {
+		return nil, err
+	}
+	want, err := decodeDigestValue(def, encoded, withPadding)
+	if err != nil {
+		return nil, err
+	}
+	h := def.New()
+	return &verifyingReader{tee: io.TeeReader(body, h), hash: h, want: want, algo: algo}, nil
+}
+
+type verifyingReader struct {
+	tee  io.Reader
+	hash hash.Hash
+	want []byte
+	algo DigestAlgorithm
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.tee.Read(p)
+	if err == io.EOF && !bytes.Equal(v.hash.Sum(nil), v.want) {
+		return n, fmt.Errorf("%w: %s", ErrDigestMismatch, v.algo)
+	}
+	return n, err
+}
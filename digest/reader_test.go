@@ -0,0 +1,101 @@
+package digest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestAddDigestReader(t *testing.T) {
+	body := []byte("johnny grab your gun")
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	rc, err := AddDigestReader(r, SHA256, bytes.NewReader(body), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		t.Fatalf("unexpected read error: %s", err)
+	}
+	got := r.Trailer.Get("content-digest")
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if got != want {
+		t.Fatalf("unexpected trailer digest: want %s, got %s", want, got)
+	}
+}
+
+func TestAddDigestReaderWithGetBody(t *testing.T) {
+	body := []byte("johnny grab your gun")
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	rc, err := AddDigestReader(r, SHA256, bytes.NewReader(body), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if got := r.Header.Get("content-digest"); got != want {
+		t.Fatalf("unexpected header digest: want %s, got %s", want, got)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected read error: %s", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatalf("unexpected body: got %s", got)
+	}
+}
+
+func TestVerifyDigestReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		body        []byte
+		withPadding bool
+		expectError bool
+	}{
+		{
+			name:        "verify sha256",
+			header:      "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:",
+			body:        []byte("johnny grab your gun"),
+			withPadding: true,
+		},
+		{
+			name:        "mismatch",
+			header:      "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:",
+			body:        []byte("a different body entirely"),
+			withPadding: true,
+			expectError: true,
+		},
+		{
+			name:        "no digest header",
+			body:        []byte("Yuji's gender is blue"),
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, _ := http.NewRequest("POST", "example.com", nil)
+			if test.header != "" {
+				r.Header.Set("content-digest", test.header)
+			}
+			reader, err := VerifyDigestReader(r, bytes.NewReader(test.body), test.withPadding)
+			if err != nil {
+				if !test.expectError {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			_, err = io.Copy(io.Discard, reader)
+			gotErr := err != nil
+			if gotErr != test.expectError {
+				if test.expectError {
+					t.Fatalf("expected error, got none")
+				} else {
+					t.Fatalf("expected no error, got: %s", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package digest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+)
+
+// AddDigestResponse computes the digest of body using algo and sets the
+// resulting Content-Digest header on w. It must be called before
+// WriteHeader or Write, since HTTP response headers cannot change once the
+// response has started; for responses whose body isn't available up front,
+// use DigestResponseWriter instead.
+func AddDigestResponse(w http.ResponseWriter, algo DigestAlgorithm, body []byte, withPadding bool) error {
+	if w.Header().Get(headerContentDigest) != "" {
+		return ErrDigestAlreadySet
+	}
+	def, err := getAlgorithm(algo)
+	if err != nil {
+		return err
+	}
+	h := def.New()
+	h.Write(body)
+	w.Header().Set(headerContentDigest, formatContentDigest(algo, h.Sum(nil), withPadding))
+	return nil
+}
+
+// VerifyDigestResponse validates the Content-Digest header on resp against
+// its body. The body is read and replaced with an equivalent in-memory
+// reader so resp.Body can still be consumed by the caller afterward.
+//
+// The whole body is buffered with no size limit; for a response whose size
+// isn't trusted (e.g. served by an untrusted peer), use
+// VerifyDigestResponseLimit instead.
+func VerifyDigestResponse(resp *http.Response, withPadding bool) error {
+	return VerifyDigestResponseLimit(resp, withPadding, 0)
+}
+
+// VerifyDigestResponseLimit is VerifyDigestResponse with a cap on how much
+// of the body it will buffer. Responses whose body exceeds maxBodyBytes
+// fail with ErrBodyTooLarge; resp.Body is then closed after a partial read
+// and is not replaced. maxBodyBytes <= 0 means no limit.
+func VerifyDigestResponseLimit(resp *http.Response, withPadding bool, maxBodyBytes int64) error {
+	body, err := readAllLimited(resp.Body, maxBodyBytes)
+	resp.Body.Close()
+	if err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			return err
+		}
+		return fmt.Errorf("digest: reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Get(headerContentDigest)
+	if header == "" {
+		return ErrMissingDigest
+	}
+	members, err := parseDigestDict(header)
+	if err != nil {
+		return err
+	}
+	algo, def, encoded, err := strongestSupported(members)
+	if err != nil {
+		return err
+	}
+	sum, err := decodeDigestValue(def, encoded, withPadding)
+	if err != nil {
+		return err
+	}
+	h := def.New()
+	h.Write(body)
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		return fmt.Errorf("%w: %s", ErrDigestMismatch, algo)
+	}
+	return nil
+}
+
+// DigestResponseWriter wraps an http.ResponseWriter, computing a digest of
+// the response body as it is written and injecting it as the Content-Digest
+// header. Since headers must precede the body, and the digest of a
+// streamed response isn't known until EOF, DigestResponseWriter buffers up
+// to the maxBufferedBytes given to NewDigestResponseWriter:
+//
+//   - if the full response fits within that limit, the Content-Digest
+//     header is set and the body is flushed once Close is called;
+//   - otherwise, once the limit is exceeded, the buffered prefix and every
+//     write after it go straight to the underlying ResponseWriter, and the
+//     digest is instead sent as an HTTP trailer on Close, using the
+//     http.TrailerPrefix convention (net/http only forwards such trailers
+//     for chunked responses, so callers must not set Content-Length).
+//
+// Close must be called once the handler is done writing.
+type DigestResponseWriter struct {
+	http.ResponseWriter
+
+	def         Algorithm
+	algo        DigestAlgorithm
+	withPadding bool
+	maxBuffered int
+
+	hash       hash.Hash
+	buf        bytes.Buffer
+	statusCode int
+	streaming  bool
+}
+
+// NewDigestResponseWriter creates a DigestResponseWriter for algo. Writes
+// are buffered up to maxBufferedBytes before falling back to a trailer; see
+// DigestResponseWriter for details.
+func NewDigestResponseWriter(w http.ResponseWriter, algo DigestAlgorithm, withPadding bool, maxBufferedBytes int) (*DigestResponseWriter, error) {
+	def, err := getAlgorithm(algo)
+	if err != nil {
+		return nil, err
+	}
+	return &DigestResponseWriter{
+		ResponseWriter: w,
+		def:            def,
+		algo:           algo,
+		withPadding:    withPadding,
+		maxBuffered:    maxBufferedBytes,
+		hash:           def.New(),
+		statusCode:     http.StatusOK,
+	}, nil
+}
+
+// WriteHeader records statusCode; it is only sent to the underlying
+// ResponseWriter once the digest (header or trailer placement) has been
+// decided, in Write or Close.
+func (d *DigestResponseWriter) WriteHeader(statusCode int) {
+	d.statusCode = statusCode
+}
+
+func (d *DigestResponseWriter) Write(p []byte) (int, error) {
+	d.hash.Write(p)
+	if d.streaming {
+		return d.ResponseWriter.Write(p)
+	}
+	d.buf.Write(p)
+	if d.buf.Len() <= d.maxBuffered {
+		return len(p), nil
+	}
+	d.streaming = true
+	d.ResponseWriter.WriteHeader(d.statusCode)
+	if _, err := d.ResponseWriter.Write(d.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush forwards to the underlying http.ResponseWriter if it implements
+// http.Flusher, so handlers that stream past maxBufferedBytes (e.g. SSE) can
+// still flush straight-through writes to the client. Before that point,
+// writes are still buffered pending the Content-Digest header placement
+// decision, so Flush is a no-op: forwarding it early would commit the
+// response with net/http's default status and no digest header.
+func (d *DigestResponseWriter) Flush() {
+	if !d.streaming {
+		return
+	}
+	if f, ok := d.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finalizes the response, sending the Content-Digest header (small
+// bodies) or trailer (bodies that exceeded maxBufferedBytes).
+func (d *DigestResponseWriter) Close() error {
+	sum := formatContentDigest(d.algo, d.hash.Sum(nil), d.withPadding)
+	if !d.streaming {
+		d.ResponseWriter.Header().Set(headerContentDigest, sum)
+		d.ResponseWriter.WriteHeader(d.statusCode)
+		_, err := d.ResponseWriter.Write(d.buf.Bytes())
+		return err
+	}
+	d.ResponseWriter.Header().Set(http.TrailerPrefix+headerContentDigest, sum)
+	return nil
+}
+
+// ResponseMiddleware wraps next so its response is digested through a
+// DigestResponseWriter, calling Close once next returns. Close is easy to
+// forget if callers construct a DigestResponseWriter themselves (it embeds
+// a plain http.ResponseWriter, which has no Close method, so nothing
+// reminds a handler to call it), so prefer this over NewDigestResponseWriter
+// directly when wiring up a handler chain.
+func ResponseMiddleware(next http.Handler, algo DigestAlgorithm, withPadding bool, maxBufferedBytes int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dw, err := NewDigestResponseWriter(w, algo, withPadding, maxBufferedBytes)
+		if err != nil {
+			http.Error(w, "digest: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer dw.Close()
+		next.ServeHTTP(dw, r)
+	})
+}
@@ -0,0 +1,187 @@
+package digest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddDigestResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	body := []byte("johnny grab your gun")
+	if err := AddDigestResponse(rec, SHA256, body, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if got := rec.Header().Get("content-digest"); got != want {
+		t.Fatalf("unexpected digest: want %s, got %s", want, got)
+	}
+
+	if err := AddDigestResponse(rec, SHA256, body, true); err != ErrDigestAlreadySet {
+		t.Fatalf("expected ErrDigestAlreadySet, got: %s", err)
+	}
+}
+
+func TestVerifyDigestResponse(t *testing.T) {
+	body := "johnny grab your gun"
+	resp := &http.Response{
+		Header: http.Header{"Content-Digest": []string{"sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"}},
+		Body:   io.NopCloser(strings.NewReader(body)),
+	}
+	if err := VerifyDigestResponse(resp, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading replaced body: %s", err)
+	}
+	if string(got) != body {
+		t.Fatalf("unexpected body after verify: %s", got)
+	}
+}
+
+func TestVerifyDigestResponseLimitRejectsOversizedBody(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Digest": []string{"sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"}},
+		Body:   io.NopCloser(strings.NewReader("johnny grab your gun")),
+	}
+	if err := VerifyDigestResponseLimit(resp, true, 4); err != ErrBodyTooLarge {
+		t.Fatalf("expected ErrBodyTooLarge, got: %v", err)
+	}
+}
+
+func TestVerifyDigestResponseMismatch(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Digest": []string{"sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"}},
+		Body:   io.NopCloser(strings.NewReader("a different body")),
+	}
+	if err := VerifyDigestResponse(resp, true); err == nil {
+		t.Fatal("expected error for mismatched digest")
+	}
+}
+
+func TestDigestResponseWriterSmallBody(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w, err := NewDigestResponseWriter(rec, SHA256, true, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w.WriteHeader(http.StatusCreated)
+	if _, err := w.Write([]byte("johnny grab your gun")); err != nil {
+		t.Fatalf("unexpected write error: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected close error: %s", err)
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if got := rec.Header().Get("content-digest"); got != want {
+		t.Fatalf("unexpected digest: want %s, got %s", want, got)
+	}
+	if got := rec.Body.String(); got != "johnny grab your gun" {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
+
+func TestResponseMiddlewareClosesWriter(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		if _, err := w.Write([]byte("hello world")); err != nil {
+			t.Fatalf("unexpected write error: %s", err)
+		}
+		// Deliberately do not call Close: that's ResponseMiddleware's job.
+	})
+	h := ResponseMiddleware(next, SHA256, true, 1024)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "hello world" {
+		t.Fatalf("expected body to be flushed without an explicit Close, got %q", got)
+	}
+	if got := rec.Header().Get("content-digest"); got == "" {
+		t.Fatal("expected content-digest header to be set")
+	}
+}
+
+func TestDigestResponseWriterFlushBeforeLimitIsNoop(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w, err := NewDigestResponseWriter(rec, SHA256, true, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	flusher, ok := interface{}(w).(http.Flusher)
+	if !ok {
+		t.Fatal("expected DigestResponseWriter to implement http.Flusher")
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("johnny grab your gun")); err != nil {
+		t.Fatalf("unexpected write error: %s", err)
+	}
+	flusher.Flush()
+	if rec.Flushed {
+		t.Fatal("expected Flush to be a no-op while still buffering, since the digest header isn't set yet")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected close error: %s", err)
+	}
+	if got := rec.Header().Get("content-digest"); got == "" {
+		t.Fatal("expected content-digest header to be set")
+	}
+}
+
+func TestDigestResponseWriterFlushesOnceStreaming(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w, err := NewDigestResponseWriter(rec, SHA256, true, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	flusher, ok := interface{}(w).(http.Flusher)
+	if !ok {
+		t.Fatal("expected DigestResponseWriter to implement http.Flusher")
+	}
+	if _, err := w.Write([]byte("johnny grab your gun")); err != nil {
+		t.Fatalf("unexpected write error: %s", err)
+	}
+	flusher.Flush()
+	if !rec.Flushed {
+		t.Fatal("expected Flush to reach the underlying ResponseWriter once streaming past the limit")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected close error: %s", err)
+	}
+}
+
+func TestDigestResponseWriterStreamsPastLimit(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w, err := NewDigestResponseWriter(rec, SHA256, true, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := w.Write([]byte("johnny grab your gun")); err != nil {
+		t.Fatalf("unexpected write error: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected close error: %s", err)
+	}
+
+	if got := rec.Header().Get("content-digest"); got != "" {
+		t.Fatalf("expected no leading content-digest header, got %s", got)
+	}
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if got := rec.Header().Get(http.TrailerPrefix + "Content-Digest"); got != want {
+		t.Fatalf("unexpected trailer digest: want %s, got %s", want, got)
+	}
+	if got := rec.Body.String(); got != "johnny grab your gun" {
+		t.Fatalf("unexpected body: %s", got)
+	}
+}
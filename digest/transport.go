@@ -0,0 +1,78 @@
+package digest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TransportOptions configures Transport.
+type TransportOptions struct {
+	DigestsOptions
+	// Algorithms lists the digest algorithms to compute and send, in
+	// descending preference. It is used unless the outgoing request
+	// already carries a Want-Content-Digest header (e.g. copied from a
+	// prior response) naming algorithms this package recognizes, in which
+	// case that preference wins. Defaults to {SHA256} if empty.
+	Algorithms []DigestAlgorithm
+	// MaxBodyBytes caps how much of the request body RoundTrip will buffer
+	// in order to compute its digest; requests whose body exceeds it fail
+	// with ErrBodyTooLarge before reaching Base. Zero means no limit.
+	MaxBodyBytes int64
+}
+
+// Transport is an http.RoundTripper that computes a Content-Digest (or,
+// with Options.Repr, Repr-Digest) for any outgoing request carrying a body
+// and injects it before delegating to Base. It composes cleanly with an
+// httpsig signing RoundTripper used as Base, since the digest header it
+// adds is present before the request reaches the signer.
+type Transport struct {
+	// Base is the underlying RoundTripper. http.DefaultTransport is used
+	// if nil.
+	Base    http.RoundTripper
+	Options TransportOptions
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return t.base().RoundTrip(req)
+	}
+
+	algos := t.Options.Algorithms
+	if want := req.Header.Get(headerWantContentDigest); want != "" {
+		if preferred := ParseWantContentDigest(want); len(preferred) > 0 {
+			algos = preferred
+		}
+	}
+	if len(algos) == 0 {
+		algos = []DigestAlgorithm{SHA256}
+	}
+
+	body, err := readAllLimited(req.Body, t.Options.MaxBodyBytes)
+	req.Body.Close()
+	if err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("digest: reading request body: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	if err := AddDigests(req, algos, body, t.Options.DigestsOptions); err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	return t.base().RoundTrip(req)
+}
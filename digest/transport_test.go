@@ -0,0 +1,92 @@
+package digest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTransportInjectsDigest(t *testing.T) {
+	var gotDigest, gotBody string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotDigest = req.Header.Get("content-digest")
+		b, _ := io.ReadAll(req.Body)
+		gotBody = string(b)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	})
+	rt := &Transport{Base: base, Options: TransportOptions{Algorithms: []DigestAlgorithm{SHA256}, DigestsOptions: DigestsOptions{WithPadding: true}}}
+
+	req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("johnny grab your gun"))
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	want := "sha-256=:RYiuVuVdRpU-BWcNUUg3sf0EbJjQ9LDj9tUqR546hhk=:"
+	if gotDigest != want {
+		t.Fatalf("unexpected digest: want %s, got %s", want, gotDigest)
+	}
+	if gotBody != "johnny grab your gun" {
+		t.Fatalf("unexpected body forwarded downstream: %s", gotBody)
+	}
+}
+
+func TestTransportHonorsWantContentDigest(t *testing.T) {
+	var gotDigest string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotDigest = req.Header.Get("content-digest")
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	})
+	rt := &Transport{Base: base, Options: TransportOptions{Algorithms: []DigestAlgorithm{SHA256}, DigestsOptions: DigestsOptions{WithPadding: true}}}
+
+	req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("johnny grab your gun"))
+	req.Header.Set("Want-Content-Digest", "sha-512=1")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if want := "sha-512"; !bytes.Contains([]byte(gotDigest), []byte(want)) {
+		t.Fatalf("expected digest to honor server preference for %s, got %s", want, gotDigest)
+	}
+}
+
+func TestTransportRejectsOversizedBody(t *testing.T) {
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("base should not be called for an oversized body")
+		return nil, nil
+	})
+	rt := &Transport{Base: base, Options: TransportOptions{MaxBodyBytes: 4}}
+
+	req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("johnny grab your gun"))
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestTransportPassesThroughBodylessRequests(t *testing.T) {
+	var called bool
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		if req.Header.Get("content-digest") != "" {
+			t.Fatal("did not expect a digest on a bodyless request")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+	})
+	rt := &Transport{Base: base}
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !called {
+		t.Fatal("expected base transport to be called")
+	}
+}
@@ -0,0 +1,94 @@
+package digest
+
+import "hash"
+
+// Legacy algorithm identifiers from the IANA Hash Algorithms for HTTP
+// Digest Fields registry, reproducing the historical BSD `sum -r` and
+// POSIX `cksum` utilities. They are deprecated and weak by modern
+// standards, so they are not registered by default; call
+// RegisterUnixChecksumAlgorithms to opt in for interop with peers that
+// still produce them.
+const (
+	UnixSum   DigestAlgorithm = "UnixSum"
+	UnixCksum DigestAlgorithm = "UnixCksum"
+)
+
+// RegisterUnixChecksumAlgorithms registers UnixSum and UnixCksum so they are
+// recognized by AddDigest, AddDigests, verifyDigest, VerifyDigests and the
+// streaming variants. Both produce much smaller digests than SHA-256 and
+// SHA-512, so a peer offering one of those alongside a legacy checksum is
+// preferred during verification.
+func RegisterUnixChecksumAlgorithms() {
+	RegisterDigestAlgorithm(UnixSum, newUnixSum, (&unixSum{}).Size())
+	RegisterDigestAlgorithm(UnixCksum, newUnixCksum, (&unixCksum{}).Size())
+}
+
+// unixSum reproduces the historical BSD `sum -r` 16-bit rotating checksum.
+type unixSum struct {
+	sum uint32
+}
+
+func newUnixSum() hash.Hash { return &unixSum{} }
+
+func (s *unixSum) Write(p []byte) (int, error) {
+	for _, b := range p {
+		s.sum = (s.sum >> 1) + ((s.sum & 1) << 15)
+		s.sum += uint32(b)
+		s.sum &= 0xffff
+	}
+	return len(p), nil
+}
+
+func (s *unixSum) Sum(b []byte) []byte {
+	return append(b, byte(s.sum>>8), byte(s.sum))
+}
+
+func (s *unixSum) Reset()         { s.sum = 0 }
+func (s *unixSum) Size() int      { return 2 }
+func (s *unixSum) BlockSize() int { return 1 }
+
+// unixCksum reproduces the POSIX `cksum` CRC-32 variant: a CRC-32/MPEG-2
+// style CRC over the data followed by its length, complemented at the end.
+type unixCksum struct {
+	crc uint32
+	n   uint64
+}
+
+func newUnixCksum() hash.Hash { return &unixCksum{} }
+
+var cksumTable = func() [256]uint32 {
+	var tab [256]uint32
+	for i := range tab {
+		c := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if c&0x80000000 != 0 {
+				c = (c << 1) ^ 0x04c11db7
+			} else {
+				c = c << 1
+			}
+		}
+		tab[i] = c
+	}
+	return tab
+}()
+
+func (s *unixCksum) Write(p []byte) (int, error) {
+	for _, b := range p {
+		s.crc = (s.crc << 8) ^ cksumTable[byte(s.crc>>24)^b]
+	}
+	s.n += uint64(len(p))
+	return len(p), nil
+}
+
+func (s *unixCksum) Sum(b []byte) []byte {
+	crc := s.crc
+	for n := s.n; n != 0; n >>= 8 {
+		crc = (crc << 8) ^ cksumTable[byte(crc>>24)^byte(n&0xff)]
+	}
+	crc = ^crc
+	return append(b, byte(crc>>24), byte(crc>>16), byte(crc>>8), byte(crc))
+}
+
+func (s *unixCksum) Reset()         { s.crc, s.n = 0, 0 }
+func (s *unixCksum) Size() int      { return 4 }
+func (s *unixCksum) BlockSize() int { return 1 }
@@ -0,0 +1,35 @@
+package digest
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUnixChecksumAlgorithms(t *testing.T) {
+	RegisterUnixChecksumAlgorithms()
+	defer delete(algorithms, UnixSum)
+	defer delete(algorithms, UnixCksum)
+
+	// johnny grab your gun -> `sum -r` reports 14671 (0x394f).
+	body := []byte("johnny grab your gun")
+
+	sum := newUnixSum()
+	sum.Write(body)
+	if got, want := sum.Sum(nil), []byte{0x39, 0x4f}; string(got) != string(want) {
+		t.Fatalf("unixsum mismatch: got % x, want % x", got, want)
+	}
+
+	r, _ := http.NewRequest("POST", "example.com", nil)
+	if err := AddDigest(r, UnixCksum, body, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := r.Header.Get("content-digest"); got == "" {
+		t.Fatal("expected content-digest header to be set")
+	}
+
+	buf := []byte(nil)
+	buf = append(buf, body...)
+	if err := VerifyDigests(r, buf, DigestsOptions{WithPadding: true}); err != nil {
+		t.Fatalf("unexpected verify error: %s", err)
+	}
+}